@@ -0,0 +1,112 @@
+package s3middleware
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CacheEntry is a single object held by Cache, keyed by bucket and the resolved SPA
+// path that produced it.
+type CacheEntry struct {
+	ETag            string
+	ContentType     string
+	ContentEncoding string
+	LastModified    time.Time
+	Body            []byte
+}
+
+// Cache is implemented by the in-process cache FilesStore places in front of S3
+// GetObject calls. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the cached entry for bucket/key, if present.
+	Get(bucket, key string) (CacheEntry, bool)
+	// Set stores or replaces the cached entry for bucket/key.
+	Set(bucket, key string, entry CacheEntry)
+}
+
+// LRUCache is the default Cache implementation, bounded by both entry count and total
+// bytes held across all cached bodies, evicting the least recently used entry first.
+type LRUCache struct {
+	mu sync.Mutex
+
+	maxEntries int
+	maxBytes   int64
+	usedBytes  int64
+
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewLRUCache creates a Cache bounded to maxEntries items and maxBytes total bytes of
+// cached bodies. A zero value for either disables that particular bound.
+func NewLRUCache(maxEntries int, maxBytes int64) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache
+func (c *LRUCache) Get(bucket, key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[cacheKey(bucket, key)]
+	if !ok {
+		return CacheEntry{}, false
+	}
+
+	c.ll.MoveToFront(el)
+
+	return el.Value.(*lruItem).entry, true
+}
+
+// Set implements Cache
+func (c *LRUCache) Set(bucket, key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := cacheKey(bucket, key)
+
+	if el, ok := c.items[k]; ok {
+		c.usedBytes += int64(len(entry.Body)) - int64(len(el.Value.(*lruItem).entry.Body))
+		el.Value.(*lruItem).entry = entry
+		c.ll.MoveToFront(el)
+	} else {
+		el := c.ll.PushFront(&lruItem{key: k, entry: entry})
+		c.items[k] = el
+		c.usedBytes += int64(len(entry.Body))
+	}
+
+	c.evict()
+}
+
+// evict removes entries from the back of the list until both bounds are satisfied,
+// must be called with mu held.
+func (c *LRUCache) evict() {
+	for (c.maxEntries > 0 && c.ll.Len() > c.maxEntries) || (c.maxBytes > 0 && c.usedBytes > c.maxBytes) {
+		el := c.ll.Back()
+		if el == nil {
+			return
+		}
+
+		c.ll.Remove(el)
+		item := el.Value.(*lruItem)
+		delete(c.items, item.key)
+		c.usedBytes -= int64(len(item.entry.Body))
+	}
+}
+
+// cacheKey combines bucket and the resolved path into a single map key, objects with
+// the same path in different buckets must not collide.
+func cacheKey(bucket, key string) string {
+	return bucket + "\x00" + key
+}
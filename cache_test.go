@@ -0,0 +1,70 @@
+package s3middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache_GetSet(t *testing.T) {
+	assert := require.New(t)
+
+	cache := NewLRUCache(0, 0)
+
+	_, ok := cache.Get("testbucket", "/index.html")
+	assert.False(ok)
+
+	cache.Set("testbucket", "/index.html", CacheEntry{ETag: `"abc"`, Body: []byte("hello")})
+
+	entry, ok := cache.Get("testbucket", "/index.html")
+	assert.True(ok)
+	assert.Equal(`"abc"`, entry.ETag)
+	assert.Equal([]byte("hello"), entry.Body)
+}
+
+func TestLRUCache_EvictsByEntryCount(t *testing.T) {
+	assert := require.New(t)
+
+	cache := NewLRUCache(1, 0)
+
+	cache.Set("testbucket", "/a.html", CacheEntry{Body: []byte("a")})
+	cache.Set("testbucket", "/b.html", CacheEntry{Body: []byte("b")})
+
+	_, ok := cache.Get("testbucket", "/a.html")
+	assert.False(ok, "oldest entry should have been evicted")
+
+	_, ok = cache.Get("testbucket", "/b.html")
+	assert.True(ok)
+}
+
+func TestLRUCache_EvictsByByteSize(t *testing.T) {
+	assert := require.New(t)
+
+	cache := NewLRUCache(0, 5)
+
+	cache.Set("testbucket", "/a.html", CacheEntry{Body: []byte("hello")})
+	cache.Set("testbucket", "/b.html", CacheEntry{Body: []byte("world")})
+
+	_, ok := cache.Get("testbucket", "/a.html")
+	assert.False(ok, "oldest entry should have been evicted to stay under the byte cap")
+
+	_, ok = cache.Get("testbucket", "/b.html")
+	assert.True(ok)
+}
+
+func TestLRUCache_DifferentBucketsDoNotCollide(t *testing.T) {
+	assert := require.New(t)
+
+	cache := NewLRUCache(0, 0)
+
+	cache.Set("bucket-a", "/index.html", CacheEntry{Body: []byte("a")})
+	cache.Set("bucket-b", "/index.html", CacheEntry{Body: []byte("b")})
+
+	entryA, ok := cache.Get("bucket-a", "/index.html")
+	assert.True(ok)
+	assert.Equal([]byte("a"), entryA.Body)
+
+	entryB, ok := cache.Get("bucket-b", "/index.html")
+	assert.True(ok)
+	assert.Equal([]byte("b"), entryB.Body)
+}
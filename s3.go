@@ -1,33 +1,70 @@
 package s3middleware
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
-	"github.com/pkg/errors"
+	pkgerrors "github.com/pkg/errors"
 )
 
 var (
-	errNotFound = errors.New("file not found")
+	errNotFound     = errors.New("file not found")
+	errInvalidRange = errors.New("invalid range")
 )
 
+const (
+	invalidRangeErrorCode = "InvalidRange"
+	notModifiedErrorCode  = "NotModified"
+)
+
+// S3API the subset of the s3 client used by this middleware, enables mocking in tests
+// and swapping in alternative implementations for S3 compatible services.
+type S3API interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
+// Presigner generates presigned S3 URLs, used by FilesStore to redirect clients to
+// large objects rather than streaming them through the Go process.
+//
+//go:generate mockgen -source=s3.go -destination=mocks/s3.go -package=mocks S3API,Presigner
+type Presigner interface {
+	PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
 // FilesConfig defines the config for the middleware
 type FilesConfig struct {
 	// Skipper defines a function to skip middleware
 	echomiddleware.Skipper
 	// Region The region used to configure the aws client
 	Region string
+	// Endpoint overrides the default S3 endpoint, used to target S3 compatible
+	// services such as MinIO, Ceph or LocalStack.
+	Endpoint string
+	// UsePathStyle forces path style addressing (bucket in the path rather than the
+	// host), required by most S3 compatible services when Endpoint is set.
+	UsePathStyle bool
+	// Credentials provides a pre-built credential provider, defaults to the standard
+	// AWS SDK credential chain when not supplied.
+	Credentials aws.CredentialsProvider
 	// HeaderXRequestID Name of the request id header to include in callbacks, defaults to echo.HeaderXRequestID
 	HeaderXRequestID string
 	// Enable SPA mode by forwarding all not-found requests to root so that
@@ -41,8 +78,31 @@ type FilesConfig struct {
 	OnErr func(ctx context.Context, err error)
 	// CacheHeaders is called prior to writing enabling customisation of cache control headers
 	CacheHeaders func(ctx context.Context, fileInfo FileInfo) string
+	// RedirectThreshold objects at or above this size, in bytes, are served as a 302
+	// redirect to a presigned S3 URL instead of being streamed through the process.
+	// Disabled when zero.
+	RedirectThreshold int64
+	// PresignExpiry how long the presigned URL used by RedirectThreshold remains
+	// valid for, defaults to 15 minutes.
+	PresignExpiry time.Duration
+	// RedirectSkipper defines a function to skip the RedirectThreshold check for a
+	// given request, falling back to streaming the object through the process.
+	RedirectSkipper echomiddleware.Skipper
+	// Cache is an optional in-process cache placed in front of S3 GetObject calls,
+	// keyed by bucket and resolved SPA path. Disabled when nil.
+	Cache Cache
+	// CacheMaxObjectSize objects larger than this, in bytes, are never written to
+	// Cache even when one is configured. A zero value disables the cap.
+	CacheMaxObjectSize int64
+	// PrecompressedEncodings enables serving precompressed variants of an object, in
+	// preference order, when advertised by the client's Accept-Encoding header, e.g.
+	// ["br", "gzip"]. "foo.js" is tried as "foo.js.br", then "foo.js.gz", falling back
+	// to "foo.js" itself. Disabled when empty.
+	PrecompressedEncodings []string
 	// S3API the s3 service used to download assets
-	S3API s3iface.S3API
+	S3API S3API
+	// Presigner used to generate the presigned URL used by RedirectThreshold
+	Presigner Presigner
 }
 
 // FileInfo provided to callbacks to enable cache header selection
@@ -66,8 +126,25 @@ func New(config FilesConfig) *FilesStore {
 	return &FilesStore{config: config}
 }
 
-// StaticBucket new static file server using the supplied s3 bucket
+// BucketResolver resolves the bucket, and an optional key prefix, to serve a given
+// request from. Returning errNotFound-equivalent behaviour is not required here: any
+// error causes StaticRouter to respond with 404 if it is errNotFound, otherwise 500,
+// and is reported via OnErr.
+type BucketResolver func(c echo.Context) (bucket, keyPrefix string, err error)
+
+// StaticBucket new static file server using the supplied s3 bucket. A thin wrapper
+// around StaticRouter for the common single-bucket case.
 func (fs *FilesStore) StaticBucket(s3Bucket string) echo.MiddlewareFunc {
+	return fs.StaticRouter(func(c echo.Context) (string, string, error) {
+		return s3Bucket, "", nil
+	})
+}
+
+// StaticRouter new static file server which resolves the bucket, and optional key
+// prefix, to serve each request from via resolver. This allows a single echo instance
+// to serve multiple SPAs/buckets, e.g. per-tenant subdomains or path prefixes, without
+// separate middleware chains.
+func (fs *FilesStore) StaticRouter(resolver BucketResolver) echo.MiddlewareFunc {
 
 	if fs.config.Skipper == nil {
 		fs.config.Skipper = echomiddleware.DefaultSkipper
@@ -93,8 +170,24 @@ func (fs *FilesStore) StaticBucket(s3Bucket string) echo.MiddlewareFunc {
 		fs.config.CacheHeaders = CacheNothing
 	}
 
-	if fs.config.S3API == nil {
-		fs.config.S3API = buildS3API(fs.config)
+	if fs.config.RedirectSkipper == nil {
+		fs.config.RedirectSkipper = echomiddleware.DefaultSkipper
+	}
+
+	if fs.config.PresignExpiry == 0 {
+		fs.config.PresignExpiry = 15 * time.Minute
+	}
+
+	if fs.config.S3API == nil || (fs.config.RedirectThreshold > 0 && fs.config.Presigner == nil) {
+		client := buildS3Client(fs.config)
+
+		if fs.config.S3API == nil {
+			fs.config.S3API = client
+		}
+
+		if fs.config.RedirectThreshold > 0 && fs.config.Presigner == nil {
+			fs.config.Presigner = s3.NewPresignClient(client)
+		}
 	}
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -114,19 +207,58 @@ func (fs *FilesStore) StaticBucket(s3Bucket string) echo.MiddlewareFunc {
 				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid request method: %s path: %s", c.Request().Method, c.Request().URL.Path))
 			}
 
+			s3Bucket, keyPrefix, err := resolver(c)
+			if err != nil {
+				fs.config.OnErr(ctx, pkgerrors.Wrapf(err, "failed to resolve bucket id: %s", id))
+				if err == errNotFound {
+					return echo.NewHTTPError(http.StatusNotFound, "document not found:", c.Request().URL.Path)
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to process request")
+			}
+
 			paths := fs.buildPaths(c)
 
-			for _, path := range paths {
-				contentType, body, err := fs.file(c, s3Bucket, id, path)
+			for _, p := range paths {
+				path := filepath.Join("/", keyPrefix, p)
+
+				// a cached entry means this path has already been fetched in full at
+				// least once; skip the HeadObject probe and let file() make the much
+				// cheaper conditional GetObject request instead of paying for both.
+				haveCached := false
+				if fs.config.Cache != nil {
+					_, haveCached = fs.config.Cache.Get(s3Bucket, path)
+				}
+
+				if fs.config.RedirectThreshold > 0 && !fs.config.RedirectSkipper(c) && !haveCached {
+					redirectURL, err := fs.redirectURL(c, s3Bucket, path)
+					if err == errNotFound {
+						continue // try the next path
+					}
+					if err != nil {
+						// fall back to streaming the object through the process rather
+						// than failing the request outright
+						fs.config.OnErr(ctx, pkgerrors.Wrapf(err, "failed to presign s3 request path: %s id: %s", path, id))
+					} else if redirectURL != "" {
+						return c.Redirect(http.StatusFound, redirectURL)
+					}
+				}
+
+				status, contentType, body, err := fs.file(c, s3Bucket, id, path)
 				if err == errNotFound {
 					continue // try the next path
 				}
+				if err == errInvalidRange {
+					return echo.NewHTTPError(http.StatusRequestedRangeNotSatisfiable, "invalid range")
+				}
 				if err != nil {
-					fs.config.OnErr(ctx, errors.Wrapf(err, "failed to process s3 request path: %s id: %s", path, id))
+					fs.config.OnErr(ctx, pkgerrors.Wrapf(err, "failed to process s3 request path: %s id: %s", path, id))
 					return echo.NewHTTPError(http.StatusInternalServerError, "failed to process request")
 				}
+				if status == http.StatusNotModified {
+					return c.NoContent(status)
+				}
 				defer body.Close()
-				return c.Stream(http.StatusOK, contentType, body)
+				return c.Stream(status, contentType, body)
 			}
 
 			// neither path was found
@@ -136,22 +268,98 @@ func (fs *FilesStore) StaticBucket(s3Bucket string) echo.MiddlewareFunc {
 	}
 }
 
-func (fs *FilesStore) file(c echo.Context, s3Bucket, id, name string) (string, io.ReadCloser, error) {
+// redirectURL probes the object size via HeadObject and, if it is at or above
+// RedirectThreshold, returns a presigned URL the client should be redirected to
+// instead of having the object streamed through the process.
+func (fs *FilesStore) redirectURL(c echo.Context, s3Bucket, name string) (string, error) {
 	ctx := c.Request().Context()
 
-	start := time.Now()
-	res, err := fs.config.S3API.GetObjectWithContext(ctx, &s3.GetObjectInput{
+	head, err := fs.config.S3API.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(s3Bucket),
 		Key:    aws.String(name),
 	})
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case s3.ErrCodeNoSuchKey:
-				return "", nil, errNotFound
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return "", errNotFound
+		}
+		return "", err
+	}
+
+	if aws.ToInt64(head.ContentLength) < fs.config.RedirectThreshold {
+		return "", nil
+	}
+
+	presigned, err := fs.config.Presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s3Bucket),
+		Key:    aws.String(name),
+	}, s3.WithPresignExpires(fs.config.PresignExpiry))
+	if err != nil {
+		return "", err
+	}
+
+	return presigned.URL, nil
+}
+
+func (fs *FilesStore) file(c echo.Context, s3Bucket, id, name string) (int, string, io.ReadCloser, error) {
+	ctx := c.Request().Context()
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s3Bucket),
+		Key:    aws.String(name),
+	}
+
+	ranged := false
+	if rng := c.Request().Header.Get("Range"); rng != "" {
+		input.Range = aws.String(rng)
+		ranged = true
+	}
+
+	clientConditional := false
+
+	if inm := c.Request().Header.Get("If-None-Match"); inm != "" {
+		input.IfNoneMatch = aws.String(inm)
+		clientConditional = true
+	}
+
+	if ims := c.Request().Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			input.IfModifiedSince = aws.Time(t)
+			clientConditional = true
+		}
+	}
+
+	var cached CacheEntry
+	haveCached := false
+
+	if !clientConditional && !ranged && fs.config.Cache != nil {
+		if entry, ok := fs.config.Cache.Get(s3Bucket, name); ok {
+			cached = entry
+			haveCached = true
+			input.IfNoneMatch = aws.String(entry.ETag)
+		}
+	}
+
+	start := time.Now()
+	res, err := fs.config.S3API.GetObject(ctx, input)
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return 0, "", nil, errNotFound
+		}
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.ErrorCode() {
+			case invalidRangeErrorCode:
+				return 0, "", nil, errInvalidRange
+			case notModifiedErrorCode:
+				if haveCached {
+					return fs.cacheHit(c, id, s3Bucket, name, cached)
+				}
+				return fs.notModified(c, id, s3Bucket, name, input)
 			}
 		}
-		return "", nil, err
+		return 0, "", nil, err
 	}
 
 	stop := time.Now()
@@ -160,9 +368,9 @@ func (fs *FilesStore) file(c echo.Context, s3Bucket, id, name string) (string, i
 		"id":            id,
 		"bucket":        s3Bucket,
 		"key":           name,
-		"etag":          aws.StringValue(res.ETag),
-		"last_modified": aws.TimeValue(res.LastModified).Format(time.RFC3339),
-		"contentlength": aws.Int64Value(res.ContentLength),
+		"etag":          aws.ToString(res.ETag),
+		"last_modified": aws.ToTime(res.LastModified).Format(time.RFC3339),
+		"contentlength": aws.ToInt64(res.ContentLength),
 		"latency":       stop.Sub(start),
 		"latency_human": stop.Sub(start).String(),
 	})
@@ -172,18 +380,154 @@ func (fs *FilesStore) file(c echo.Context, s3Bucket, id, name string) (string, i
 		ID:            id,
 		Name:          name,
 		Bucket:        s3Bucket,
-		Etag:          aws.StringValue(res.ETag),
-		LastModified:  aws.TimeValue(res.LastModified),
-		ContentLength: aws.Int64Value(res.ContentLength),
+		Etag:          aws.ToString(res.ETag),
+		LastModified:  aws.ToTime(res.LastModified),
+		ContentLength: aws.ToInt64(res.ContentLength),
 	}))
 
 	// add this information to help with troubleshooting
-	c.Response().Header().Set("ETag", aws.StringValue(res.ETag))
-	c.Response().Header().Set("Last-Modified", aws.TimeValue(res.LastModified).Format(time.RFC3339))
+	c.Response().Header().Set("ETag", aws.ToString(res.ETag))
+	c.Response().Header().Set("Last-Modified", aws.ToTime(res.LastModified).Format(time.RFC3339))
+	c.Response().Header().Set("Accept-Ranges", "bytes")
+
+	// we rely on s3 for content type of objects, unless a precompressed variant was
+	// served, in which case the suffix would otherwise shadow the real MIME type
+	contentType := aws.ToString(res.ContentType)
+	contentEncoding := ""
+
+	if len(fs.config.PrecompressedEncodings) > 0 {
+		if enc, original, ok := stripPrecompressedSuffix(name); ok {
+			contentEncoding = enc
+			if mimeType := mime.TypeByExtension(filepath.Ext(original)); mimeType != "" {
+				contentType = mimeType
+			}
+			c.Response().Header().Set("Content-Encoding", contentEncoding)
+			c.Response().Header().Set("Vary", "Accept-Encoding")
+		}
+	}
+
+	status := http.StatusOK
+
+	if res.ContentRange != nil {
+		c.Response().Header().Set("Content-Range", aws.ToString(res.ContentRange))
+		status = http.StatusPartialContent
+	}
+
+	body := res.Body
+
+	if fs.config.Cache != nil && status == http.StatusOK {
+		body = fs.teeToCache(res, s3Bucket, name, contentType, contentEncoding)
+	}
+
+	return status, contentType, body, nil
+}
+
+// cacheHit serves a cached object after S3 confirms, via NotModified, that the cached
+// ETag is still current.
+func (fs *FilesStore) cacheHit(c echo.Context, id, s3Bucket, name string, entry CacheEntry) (int, string, io.ReadCloser, error) {
+	ctx := c.Request().Context()
 
-	// we rely on s3 for content type of objects
-	// return c.Stream(http.StatusOK, aws.StringValue(res.ContentType), res.Body)
-	return aws.StringValue(res.ContentType), res.Body, nil
+	c.Response().Header().Set("Cache-Control", fs.config.CacheHeaders(ctx, FileInfo{
+		ID:            id,
+		Name:          name,
+		Bucket:        s3Bucket,
+		Etag:          entry.ETag,
+		LastModified:  entry.LastModified,
+		ContentLength: int64(len(entry.Body)),
+	}))
+	c.Response().Header().Set("ETag", entry.ETag)
+	c.Response().Header().Set("Last-Modified", entry.LastModified.Format(time.RFC3339))
+	c.Response().Header().Set("Accept-Ranges", "bytes")
+
+	if entry.ContentEncoding != "" {
+		c.Response().Header().Set("Content-Encoding", entry.ContentEncoding)
+		c.Response().Header().Set("Vary", "Accept-Encoding")
+	}
+
+	return http.StatusOK, entry.ContentType, ioutil.NopCloser(bytes.NewReader(entry.Body)), nil
+}
+
+// teeToCache wraps the S3 response body so that, once it has been fully read by the
+// client, the bytes are written into Cache. Objects without a known Content-Length or
+// larger than CacheMaxObjectSize are streamed through untouched. A zero or negative
+// CacheMaxObjectSize means no size cap, matching LRUCache's own zero-value convention.
+func (fs *FilesStore) teeToCache(res *s3.GetObjectOutput, s3Bucket, name, contentType, contentEncoding string) io.ReadCloser {
+	size := aws.ToInt64(res.ContentLength)
+	if size <= 0 {
+		return res.Body
+	}
+
+	if fs.config.CacheMaxObjectSize > 0 && size > fs.config.CacheMaxObjectSize {
+		return res.Body
+	}
+
+	entry := CacheEntry{
+		ETag:            aws.ToString(res.ETag),
+		ContentType:     contentType,
+		ContentEncoding: contentEncoding,
+		LastModified:    aws.ToTime(res.LastModified),
+	}
+
+	return &cacheTeeReader{
+		body: res.Body,
+		buf:  bytes.NewBuffer(make([]byte, 0, size)),
+		commit: func(body []byte) {
+			entry.Body = body
+			fs.config.Cache.Set(s3Bucket, name, entry)
+		},
+	}
+}
+
+// cacheTeeReader copies bytes into buf as they are read, committing them to the cache
+// once the underlying body has been read to completion.
+type cacheTeeReader struct {
+	body   io.ReadCloser
+	buf    *bytes.Buffer
+	commit func(body []byte)
+	done   bool
+}
+
+func (t *cacheTeeReader) Read(p []byte) (int, error) {
+	n, err := t.body.Read(p)
+	if n > 0 {
+		t.buf.Write(p[:n])
+	}
+	if err == io.EOF && !t.done {
+		t.done = true
+		t.commit(t.buf.Bytes())
+	}
+	return n, err
+}
+
+func (t *cacheTeeReader) Close() error {
+	return t.body.Close()
+}
+
+// notModified handles the 304 response from S3 when a conditional GET request
+// (If-None-Match / If-Modified-Since) matches the object already held by the client.
+// S3 does not return object metadata alongside a 304, so the headers echoed back are
+// the ones the client already believes are current.
+func (fs *FilesStore) notModified(c echo.Context, id, s3Bucket, name string, input *s3.GetObjectInput) (int, string, io.ReadCloser, error) {
+	ctx := c.Request().Context()
+
+	fileInfo := FileInfo{
+		ID:     id,
+		Name:   name,
+		Bucket: s3Bucket,
+		Etag:   aws.ToString(input.IfNoneMatch),
+	}
+
+	if input.IfModifiedSince != nil {
+		fileInfo.LastModified = *input.IfModifiedSince
+	}
+
+	c.Response().Header().Set("Cache-Control", fs.config.CacheHeaders(ctx, fileInfo))
+	c.Response().Header().Set("ETag", fileInfo.Etag)
+	if !fileInfo.LastModified.IsZero() {
+		c.Response().Header().Set("Last-Modified", fileInfo.LastModified.Format(time.RFC3339))
+	}
+
+	return http.StatusNotModified, "", nil, nil
 }
 
 func (fs *FilesStore) buildPaths(c echo.Context) []string {
@@ -199,7 +543,105 @@ func (fs *FilesStore) buildPaths(c echo.Context) []string {
 		p = append(p, filepath.Join("/", fs.config.Index))
 	}
 
-	return p
+	return fs.withPrecompressed(c, p)
+}
+
+// precompressedExtensions maps a Content-Encoding token, as it would appear in an
+// Accept-Encoding header, to the file extension operators are expected to upload the
+// precompressed variant under.
+var precompressedExtensions = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+// withPrecompressed prepends, for each path, the precompressed variants advertised by
+// the client's Accept-Encoding header and enabled via PrecompressedEncodings, in the
+// configured preference order, so they are tried before the uncompressed original.
+func (fs *FilesStore) withPrecompressed(c echo.Context, paths []string) []string {
+	if len(fs.config.PrecompressedEncodings) == 0 {
+		return paths
+	}
+
+	acceptEncoding := c.Request().Header.Get("Accept-Encoding")
+	if acceptEncoding == "" {
+		return paths
+	}
+
+	out := make([]string, 0, len(paths)*2)
+
+	for _, path := range paths {
+		for _, enc := range fs.config.PrecompressedEncodings {
+			ext, ok := precompressedExtensions[enc]
+			if !ok || !acceptsEncoding(acceptEncoding, enc) {
+				continue
+			}
+			out = append(out, path+ext)
+		}
+		out = append(out, path)
+	}
+
+	return out
+}
+
+// acceptsEncoding reports whether acceptEncoding, the raw value of an Accept-Encoding
+// header, admits enc. It parses the header into its comma-separated tokens rather than
+// doing a raw substring match, so that a "q=0" weight explicitly ruling out enc (or "*")
+// is honoured instead of being treated as acceptance.
+func acceptsEncoding(acceptEncoding, enc string) bool {
+	accepted := false
+
+	for _, token := range strings.Split(acceptEncoding, ",") {
+		parts := strings.SplitN(strings.TrimSpace(token), ";", 2)
+		name := strings.TrimSpace(parts[0])
+
+		qValue := ""
+		if len(parts) == 2 {
+			qValue = parts[1]
+		}
+
+		if name != enc && name != "*" {
+			continue
+		}
+
+		if rejectsViaQValue(qValue) {
+			if name == enc {
+				return false
+			}
+			continue
+		}
+
+		accepted = true
+	}
+
+	return accepted
+}
+
+// rejectsViaQValue reports whether qValue, the portion of an Accept-Encoding token
+// after the ";", carries an explicit "q=0" weight.
+func rejectsViaQValue(qValue string) bool {
+	qValue = strings.TrimSpace(qValue)
+	if !strings.HasPrefix(qValue, "q=") {
+		return false
+	}
+
+	q, err := strconv.ParseFloat(strings.TrimPrefix(qValue, "q="), 64)
+	if err != nil {
+		return false
+	}
+
+	return q == 0
+}
+
+// stripPrecompressedSuffix reports whether name carries one of the known precompressed
+// extensions, returning the matching Content-Encoding token and the name with the
+// extension removed so content-type lookups see the original file extension.
+func stripPrecompressedSuffix(name string) (encoding, original string, ok bool) {
+	for enc, ext := range precompressedExtensions {
+		if strings.HasSuffix(name, ext) {
+			return enc, strings.TrimSuffix(name, ext), true
+		}
+	}
+	return "", name, false
 }
 
 // CacheNothing default cache header function which caches nothing
@@ -207,19 +649,32 @@ func CacheNothing(ctx context.Context, fileInfo FileInfo) string {
 	return "no-store, no-cache, must-revalidate, post-check=0, pre-check=0"
 }
 
-func buildS3API(config FilesConfig) s3iface.S3API {
-	awsCfg := buildAwsConfig(config) // update the region / profile
+func buildS3Client(filesConfig FilesConfig) *s3.Client {
+	awsCfg := buildAwsConfig(filesConfig)
 
-	sess := session.Must(session.NewSession(awsCfg))
-	return s3.New(sess)
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if filesConfig.Endpoint != "" {
+			o.BaseEndpoint = aws.String(filesConfig.Endpoint)
+		}
+		o.UsePathStyle = filesConfig.UsePathStyle
+	})
 }
 
-func buildAwsConfig(config FilesConfig) *aws.Config {
-	awsCfg := &aws.Config{}
+func buildAwsConfig(filesConfig FilesConfig) aws.Config {
+	var optFns []func(*config.LoadOptions) error
+
+	if filesConfig.Region != "" {
+		optFns = append(optFns, config.WithRegion(filesConfig.Region))
+	}
 
-	if config.Region != "" {
-		awsCfg = awsCfg.WithRegion(config.Region)
+	if filesConfig.Credentials != nil {
+		optFns = append(optFns, config.WithCredentialsProvider(filesConfig.Credentials))
 	}
 
+	// this only fails when a loaded config file or env var is malformed, the zero value
+	// aws.Config is still safe to use so we ignore it here rather than plumb an error
+	// through New/StaticBucket.
+	awsCfg, _ := config.LoadDefaultConfig(context.Background(), optFns...)
+
 	return awsCfg
 }
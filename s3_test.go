@@ -8,9 +8,11 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
@@ -27,9 +29,9 @@ func TestStatic(t *testing.T) {
 
 	r := ioutil.NopCloser(strings.NewReader("hello world"))
 
-	s3svc.EXPECT().GetObjectWithContext(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/index.html")}).Return(&s3.GetObjectOutput{Body: r}, nil)
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/index.html")}).Return(&s3.GetObjectOutput{Body: r}, nil)
 
-	fs := FilesStore{s3svc: s3svc}
+	fs := FilesStore{config: FilesConfig{S3API: s3svc}}
 
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/index.html", nil)
@@ -43,6 +45,412 @@ func TestStatic(t *testing.T) {
 	assert.Equal(http.StatusOK, rec.Code)
 }
 
+func TestStatic_Range(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+
+	r := ioutil.NopCloser(strings.NewReader("llo w"))
+
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{
+		Bucket: aws.String("testbucket"),
+		Key:    aws.String("/index.html"),
+		Range:  aws.String("bytes=2-6"),
+	}).Return(&s3.GetObjectOutput{Body: r, ContentRange: aws.String("bytes 2-6/11")}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("Range", "bytes=2-6")
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusPartialContent, rec.Code)
+	assert.Equal("bytes 2-6/11", rec.Header().Get("Content-Range"))
+	assert.Equal("bytes", rec.Header().Get("Accept-Ranges"))
+}
+
+func TestStatic_InvalidRange(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{
+		Bucket: aws.String("testbucket"),
+		Key:    aws.String("/index.html"),
+		Range:  aws.String("bytes=9000-9999"),
+	}).Return(nil, &smithy.GenericAPIError{Code: "InvalidRange"})
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	req.Header.Set("Range", "bytes=9000-9999")
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusRequestedRangeNotSatisfiable, rec.Code)
+}
+
+func TestStatic_ConditionalGet_Hit(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+
+	r := ioutil.NopCloser(strings.NewReader("hello world"))
+
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{
+		Bucket:      aws.String("testbucket"),
+		Key:         aws.String("/index.html"),
+		IfNoneMatch: aws.String(`"stale-etag"`),
+	}).Return(&s3.GetObjectOutput{Body: r, ETag: aws.String(`"fresh-etag"`)}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	req.Header.Set("If-None-Match", `"stale-etag"`)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal(`"fresh-etag"`, rec.Header().Get("ETag"))
+}
+
+func TestStatic_ConditionalGet_NotModified(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{
+		Bucket:      aws.String("testbucket"),
+		Key:         aws.String("/index.html"),
+		IfNoneMatch: aws.String(`"current-etag"`),
+	}).Return(nil, &smithy.GenericAPIError{Code: "NotModified"})
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	req.Header.Set("If-None-Match", `"current-etag"`)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusNotModified, rec.Code)
+	assert.Equal(`"current-etag"`, rec.Header().Get("ETag"))
+	assert.Empty(rec.Body.Bytes())
+}
+
+func TestStatic_RedirectThreshold(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+	presigner := mocks.NewMockPresigner(ctrl)
+
+	s3svc.EXPECT().HeadObject(gomock.Any(), &s3.HeadObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/video.mp4")}).
+		Return(&s3.HeadObjectOutput{ContentLength: aws.Int64(100)}, nil)
+
+	presigner.EXPECT().PresignGetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/video.mp4")}, gomock.Any()).
+		Return(&v4.PresignedHTTPRequest{URL: "https://testbucket.s3.amazonaws.com/video.mp4?X-Amz-Signature=abc"}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc, Presigner: presigner, RedirectThreshold: 10}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/video.mp4", nil)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusFound, rec.Code)
+	assert.Equal("https://testbucket.s3.amazonaws.com/video.mp4?X-Amz-Signature=abc", rec.Header().Get("Location"))
+}
+
+func TestStatic_RedirectThreshold_BelowThreshold(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+
+	s3svc.EXPECT().HeadObject(gomock.Any(), &s3.HeadObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/index.html")}).
+		Return(&s3.HeadObjectOutput{ContentLength: aws.Int64(5)}, nil)
+
+	r := ioutil.NopCloser(strings.NewReader("hello"))
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/index.html")}).
+		Return(&s3.GetObjectOutput{Body: r}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc, RedirectThreshold: 100}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+}
+
+func TestStatic_RedirectThreshold_PresignFails(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+	presigner := mocks.NewMockPresigner(ctrl)
+
+	s3svc.EXPECT().HeadObject(gomock.Any(), &s3.HeadObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/video.mp4")}).
+		Return(&s3.HeadObjectOutput{ContentLength: aws.Int64(100)}, nil)
+
+	presigner.EXPECT().PresignGetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/video.mp4")}, gomock.Any()).
+		Return(nil, errors.New("boom"))
+
+	r := ioutil.NopCloser(strings.NewReader("video bytes"))
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/video.mp4")}).
+		Return(&s3.GetObjectOutput{Body: r}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc, Presigner: presigner, RedirectThreshold: 10}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/video.mp4", nil)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("video bytes", rec.Body.String())
+}
+
+func TestStatic_RedirectThreshold_SkippedWhenCached(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+	presigner := mocks.NewMockPresigner(ctrl)
+	cache := NewLRUCache(10, 1<<20)
+	cache.Set("testbucket", "/video.mp4", CacheEntry{ETag: `"abc"`, Body: []byte("video bytes")})
+
+	// no HeadObject or PresignGetObject call is expected: a cached entry means the
+	// path has already been fetched, so the conditional GetObject below is enough.
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{
+		Bucket:      aws.String("testbucket"),
+		Key:         aws.String("/video.mp4"),
+		IfNoneMatch: aws.String(`"abc"`),
+	}).Return(nil, &smithy.GenericAPIError{Code: "NotModified"})
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc, Presigner: presigner, Cache: cache, CacheMaxObjectSize: 1 << 20, RedirectThreshold: 10}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/video.mp4", nil)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("video bytes", rec.Body.String())
+}
+
+func TestStatic_Cache_MissThenHit(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+	cache := NewLRUCache(10, 1<<20)
+
+	r := ioutil.NopCloser(strings.NewReader("hello world"))
+
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/index.html")}).
+		Return(&s3.GetObjectOutput{Body: r, ETag: aws.String(`"abc"`), ContentLength: aws.Int64(11)}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc, Cache: cache, CacheMaxObjectSize: 1 << 20}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("hello world", rec.Body.String())
+
+	entry, ok := cache.Get("testbucket", "/index.html")
+	assert.True(ok)
+	assert.Equal(`"abc"`, entry.ETag)
+	assert.Equal([]byte("hello world"), entry.Body)
+
+	// second request is served from the cache once S3 confirms the ETag is unchanged
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{
+		Bucket:      aws.String("testbucket"),
+		Key:         aws.String("/index.html"),
+		IfNoneMatch: aws.String(`"abc"`),
+	}).Return(nil, &smithy.GenericAPIError{Code: "NotModified"})
+
+	req = httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	rec = httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("hello world", rec.Body.String())
+	assert.Equal(`"abc"`, rec.Header().Get("ETag"))
+}
+
+func TestStatic_Cache_MaxObjectSizeUnset(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+	cache := NewLRUCache(10, 1<<20)
+
+	r := ioutil.NopCloser(strings.NewReader("hello world"))
+
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/index.html")}).
+		Return(&s3.GetObjectOutput{Body: r, ETag: aws.String(`"abc"`), ContentLength: aws.Int64(11)}, nil)
+
+	// CacheMaxObjectSize is left at its zero value, which must mean "no cap" rather
+	// than "never cache" to match LRUCache's own zero-value convention.
+	fs := FilesStore{config: FilesConfig{S3API: s3svc, Cache: cache}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("hello world", rec.Body.String())
+
+	entry, ok := cache.Get("testbucket", "/index.html")
+	assert.True(ok)
+	assert.Equal([]byte("hello world"), entry.Body)
+}
+
+func TestStatic_Precompressed_Brotli(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+
+	r := ioutil.NopCloser(strings.NewReader("compressed"))
+
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/app.js.br")}).
+		Return(&s3.GetObjectOutput{Body: r, ContentType: aws.String("application/x-brotli")}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc, PrecompressedEncodings: []string{"br", "gzip"}}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("br", rec.Header().Get("Content-Encoding"))
+	assert.Equal("Accept-Encoding", rec.Header().Get("Vary"))
+	assert.Equal("text/javascript; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+}
+
+func TestStatic_Precompressed_FallsBackWhenVariantMissing(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+
+	r := ioutil.NopCloser(strings.NewReader("plain"))
+
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/app.js.br")}).
+		Return(nil, &types.NoSuchKey{})
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/app.js.gz")}).
+		Return(nil, &types.NoSuchKey{})
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/app.js")}).
+		Return(&s3.GetObjectOutput{Body: r}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc, PrecompressedEncodings: []string{"br", "gzip"}}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Empty(rec.Header().Get("Content-Encoding"))
+}
+
+func TestStatic_Precompressed_HonoursZeroQValue(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+
+	r := ioutil.NopCloser(strings.NewReader("compressed"))
+
+	// "br;q=0" explicitly rules out Brotli, so only the gzip variant should be tried.
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/app.js.gz")}).
+		Return(&s3.GetObjectOutput{Body: r, ContentType: aws.String("application/gzip")}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc, PrecompressedEncodings: []string{"br", "gzip"}}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	req.Header.Set("Accept-Encoding", "br;q=0, gzip")
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticBucket("testbucket"))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("gzip", rec.Header().Get("Content-Encoding"))
+}
+
 func TestStatic_NotFound(t *testing.T) {
 	assert := require.New(t)
 
@@ -51,10 +459,10 @@ func TestStatic_NotFound(t *testing.T) {
 
 	s3svc := mocks.NewMockS3API(ctrl)
 
-	s3svc.EXPECT().GetObjectWithContext(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/not.html")}).
-		Return(nil, awserr.New(s3.ErrCodeNoSuchKey, "testing not found", errors.New("test")))
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/not.html")}).
+		Return(nil, &types.NoSuchKey{})
 
-	fs := FilesStore{s3svc: s3svc}
+	fs := FilesStore{config: FilesConfig{S3API: s3svc}}
 
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/not.html", nil)
@@ -76,10 +484,10 @@ func TestStatic_InternalServerError(t *testing.T) {
 
 	s3svc := mocks.NewMockS3API(ctrl)
 
-	s3svc.EXPECT().GetObjectWithContext(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/not.html")}).
-		Return(nil, awserr.New(s3.ErrCodeNoSuchBucket, "testing internal error", errors.New("test")))
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("testbucket"), Key: aws.String("/not.html")}).
+		Return(nil, &types.NoSuchBucket{})
 
-	fs := FilesStore{s3svc: s3svc}
+	fs := FilesStore{config: FilesConfig{S3API: s3svc}}
 
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/not.html", nil)
@@ -93,12 +501,74 @@ func TestStatic_InternalServerError(t *testing.T) {
 	assert.Equal(http.StatusInternalServerError, rec.Code)
 }
 
+func TestStatic_Router_ResolvesBucketAndPrefix(t *testing.T) {
+	assert := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s3svc := mocks.NewMockS3API(ctrl)
+
+	r := ioutil.NopCloser(strings.NewReader("docs"))
+
+	s3svc.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{Bucket: aws.String("docsbucket"), Key: aws.String("/docs/guide.html")}).
+		Return(&s3.GetObjectOutput{Body: r}, nil)
+
+	fs := FilesStore{config: FilesConfig{S3API: s3svc}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/guide.html", nil)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticRouter(func(c echo.Context) (string, string, error) {
+		return "docsbucket", "/docs", nil
+	}))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("docs", rec.Body.String())
+}
+
+func TestStatic_Router_ResolverNotFound(t *testing.T) {
+	assert := require.New(t)
+
+	fs := FilesStore{config: FilesConfig{S3API: mocks.NewMockS3API(gomock.NewController(t))}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/guide.html", nil)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticRouter(func(c echo.Context) (string, string, error) {
+		return "", "", errNotFound
+	}))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusNotFound, rec.Code)
+}
+
+func TestStatic_Router_ResolverError(t *testing.T) {
+	assert := require.New(t)
+
+	fs := FilesStore{config: FilesConfig{S3API: mocks.NewMockS3API(gomock.NewController(t))}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/guide.html", nil)
+	rec := httptest.NewRecorder()
+	e.Use(fs.StaticRouter(func(c echo.Context) (string, string, error) {
+		return "", "", errors.New("no tenant configured for host")
+	}))
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusInternalServerError, rec.Code)
+}
+
 func TestBuildAWSConfig(t *testing.T) {
 	assert := require.New(t)
 
 	awsCfg := buildAwsConfig(FilesConfig{})
-	assert.Equal(&aws.Config{}, awsCfg)
+	assert.Equal("", awsCfg.Region)
 
 	awsCfg = buildAwsConfig(FilesConfig{Region: "us-east-1"})
-	assert.Equal(aws.String("us-east-1"), awsCfg.Region)
+	assert.Equal("us-east-1", awsCfg.Region)
 }